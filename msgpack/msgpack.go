@@ -0,0 +1,37 @@
+/* Package msgpack registers a MessagePack Renderer with
+github.com/webpkgs/render. Import it for its side effect to make
+application/msgpack available to Render's Accept negotiation:
+
+	import _ "github.com/webpkgs/render/msgpack"
+*/
+package msgpack
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/webpkgs/render"
+)
+
+func init() {
+	m := new(MsgPack)
+	render.Register("application/msgpack", m)
+	render.Register("application/x-msgpack", m)
+}
+
+// MsgPack implements render.Renderer by encoding obj with
+// github.com/vmihailenco/msgpack.
+type MsgPack struct{}
+
+// Render implements render.Renderer.
+func (m *MsgPack) Render(w http.ResponseWriter, r *http.Request, httpCode int, obj interface{}) error {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/msgpack")
+	}
+	w.Header().Set("Date", time.Now().Format(time.RFC1123Z))
+
+	w.WriteHeader(httpCode)
+
+	return msgpack.NewEncoder(w).Encode(obj)
+}
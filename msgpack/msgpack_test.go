@@ -0,0 +1,30 @@
+package msgpack
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	vmsgpack "github.com/vmihailenco/msgpack/v5"
+)
+
+func TestMsgPackRenderEncodesObject(t *testing.T) {
+	m := new(MsgPack)
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	if err := m.Render(w, req, 200, map[string]string{"msg": "hi"}); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	if ct := w.Result().Header.Get("Content-Type"); ct != "application/msgpack" {
+		t.Fatalf("expected application/msgpack, got %q", ct)
+	}
+
+	var got map[string]string
+	if err := vmsgpack.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding body: %v", err)
+	}
+	if got["msg"] != "hi" {
+		t.Fatalf("expected msg=hi, got %v", got)
+	}
+}
@@ -0,0 +1,140 @@
+package render
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mediaRange is one entry of a parsed Accept header, e.g.
+// "application/json;q=0.8" becomes {type: "application", subtype:
+// "json", params: {}, q: 0.8}; the q parameter is kept only in q.
+type mediaRange struct {
+	typ, subtype string
+	params       map[string]string
+	q            float64
+	order        int
+}
+
+// parseAccept splits an Accept header into its media-ranges, sorted by
+// q descending; ranges with equal q stay in the order the client
+// listed them.
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+
+	for i, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		segments := strings.Split(part, ";")
+		typeParts := strings.SplitN(strings.TrimSpace(segments[0]), "/", 2)
+		if len(typeParts) != 2 {
+			continue
+		}
+
+		mr := mediaRange{
+			typ:     strings.TrimSpace(typeParts[0]),
+			subtype: strings.TrimSpace(typeParts[1]),
+			params:  map[string]string{},
+			q:       1.0,
+			order:   i,
+		}
+
+		for _, seg := range segments[1:] {
+			seg = strings.TrimSpace(seg)
+			kv := strings.SplitN(seg, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+			if key == "q" {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					mr.q = q
+				}
+				continue
+			}
+			mr.params[key] = value
+		}
+
+		ranges = append(ranges, mr)
+	}
+
+	// Sort by q descending; ties keep the client's original ordering.
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	return ranges
+}
+
+// specificity ranks how precisely a media-range matched an offered
+// content type, per RFC 7231 5.3.2: exact match beats a subtype
+// wildcard beats a full wildcard, and a range with more parameters
+// beats one with fewer once the type match is otherwise equal.
+func specificity(mr mediaRange, offeredType, offeredSubtype string) int {
+	switch {
+	case mr.typ == offeredType && mr.subtype == offeredSubtype:
+		return 2<<16 + len(mr.params)
+	case mr.typ == offeredType && mr.subtype == "*":
+		return 1<<16 + len(mr.params)
+	case mr.typ == "*" && mr.subtype == "*":
+		return len(mr.params)
+	default:
+		return -1
+	}
+}
+
+// Negotiate picks the best of the offered content types for the given
+// Accept header, honoring quality factors, type/subtype wildcards, and
+// the RFC 7231 5.3.2 specificity rules (exact match beats a subtype
+// wildcard beats a full wildcard, more params beats fewer). It returns
+// the chosen offered content type, any params the client asked for on
+// the winning media-range (e.g. "charset"), and false if nothing
+// acceptable was offered.
+func Negotiate(acceptHeader string, offered []string) (best string, params map[string]string, ok bool) {
+	if acceptHeader == "" {
+		if len(offered) == 0 {
+			return "", nil, false
+		}
+		return offered[0], nil, true
+	}
+
+	ranges := parseAccept(acceptHeader)
+
+	// ranges is already sorted by q descending, so the first group of
+	// equal-q ranges that matches any offered type holds the client's
+	// most preferred acceptable choice; within that group the most
+	// specific match wins, and equal scores keep the earlier one.
+	bestScore := -1
+	for i, mr := range ranges {
+		if mr.q == 0 {
+			continue
+		}
+
+		for _, offer := range offered {
+			offerParts := strings.SplitN(offer, "/", 2)
+			if len(offerParts) != 2 {
+				continue
+			}
+
+			score := specificity(mr, offerParts[0], offerParts[1])
+			if score < 0 {
+				continue
+			}
+			if score > bestScore {
+				bestScore = score
+				best = offer
+				params = mr.params
+				ok = true
+			}
+		}
+
+		if ok && (i+1 == len(ranges) || ranges[i+1].q < mr.q) {
+			return best, params, ok
+		}
+	}
+
+	return best, params, ok
+}
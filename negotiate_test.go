@@ -0,0 +1,40 @@
+package render
+
+import "testing"
+
+func TestNegotiateHonorsQValueOverRegistrationOrder(t *testing.T) {
+	best, _, ok := Negotiate("application/xml;q=0.9, application/json;q=0.1", []string{"application/json", "application/xml"})
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	if best != "application/xml" {
+		t.Fatalf("expected application/xml (higher q), got %s", best)
+	}
+}
+
+func TestNegotiatePrefersExactMatchOverWildcardWithinSameRange(t *testing.T) {
+	best, _, ok := Negotiate("application/*;q=0.5, application/json;q=0.5", []string{"application/json", "application/xml"})
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	if best != "application/json" {
+		t.Fatalf("expected application/json (exact match), got %s", best)
+	}
+}
+
+func TestNegotiateSkipsZeroQRanges(t *testing.T) {
+	best, _, ok := Negotiate("application/json;q=0, application/xml", []string{"application/json", "application/xml"})
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	if best != "application/xml" {
+		t.Fatalf("expected application/xml, got %s", best)
+	}
+}
+
+func TestNegotiateNoAcceptableOffer(t *testing.T) {
+	_, _, ok := Negotiate("application/pdf", []string{"application/json", "application/xml"})
+	if ok {
+		t.Fatal("expected no match")
+	}
+}
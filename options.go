@@ -0,0 +1,264 @@
+package render
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// RenderOption wraps the http.ResponseWriter a Renderer writes to,
+// letting callers opt into things like compression or conditional
+// requests per call instead of rewriting handler code:
+//
+//	render.Render(w, r, http.StatusOK, obj, render.WithCompression(), render.WithETag())
+//
+// If done is true, the option has already written the full response
+// (e.g. a 304) and Render must not invoke the Renderer at all.
+type RenderOption func(w http.ResponseWriter, r *http.Request, status int, obj interface{}) (wrapped http.ResponseWriter, done bool, err error)
+
+// applyOptions runs opts in order, each wrapping the writer returned by
+// the last, and returns the final writer to hand to the Renderer along
+// with whether the response has already been fully written.
+func applyOptions(w http.ResponseWriter, r *http.Request, status int, obj interface{}, opts []RenderOption) (http.ResponseWriter, bool, error) {
+	rw := w
+	for _, opt := range opts {
+		wrapped, done, err := opt(rw, r, status, obj)
+		if err != nil {
+			return rw, false, err
+		}
+		rw = wrapped
+		if done {
+			return rw, true, nil
+		}
+	}
+	return rw, false, nil
+}
+
+// closeIfCloser closes w if it implements io.Closer, so that buffered
+// output such as a gzip.Writer or the ETag buffer is flushed. Only w
+// itself is checked; the built-in wrappers close what they wrap in turn.
+func closeIfCloser(w http.ResponseWriter) error {
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(io.Discard) },
+}
+
+var brotliWriterPool = sync.Pool{
+	New: func() interface{} { return brotli.NewWriter(io.Discard) },
+}
+
+// compressWriter forwards Write through a pooled compressor and closes
+// (and returns to its pool) both the compressor and whatever the
+// previous RenderOption wrapped on Close.
+type compressWriter struct {
+	http.ResponseWriter
+	zw      io.WriteCloser
+	release func()
+}
+
+func (c *compressWriter) Write(p []byte) (int, error) {
+	return c.zw.Write(p)
+}
+
+func (c *compressWriter) Close() error {
+	err := c.zw.Close()
+	c.release()
+	if cerr := closeIfCloser(c.ResponseWriter); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// acceptEncoding is one entry of a parsed Accept-Encoding header, e.g.
+// "gzip;q=0.8" becomes {name: "gzip", q: 0.8}.
+type acceptEncoding struct {
+	name string
+	q    float64
+}
+
+// parseAcceptEncoding splits an Accept-Encoding header into its
+// codings, each defaulting to q=1 absent an explicit q parameter.
+func parseAcceptEncoding(header string) []acceptEncoding {
+	var encodings []acceptEncoding
+
+	for _, part := range strings.Split(header, ",") {
+		segments := strings.Split(part, ";")
+		name := strings.TrimSpace(segments[0])
+		if name == "" {
+			continue
+		}
+
+		enc := acceptEncoding{name: name, q: 1.0}
+		for _, seg := range segments[1:] {
+			kv := strings.SplitN(strings.TrimSpace(seg), "=", 2)
+			if len(kv) != 2 || strings.TrimSpace(kv[0]) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64); err == nil {
+				enc.q = q
+			}
+		}
+		encodings = append(encodings, enc)
+	}
+
+	return encodings
+}
+
+// encodingQ returns name's q-value from encodings, falling back to the
+// "*" wildcard's q-value if name isn't listed explicitly. The second
+// return value is false if name is acceptable under neither.
+func encodingQ(encodings []acceptEncoding, name string) (float64, bool) {
+	wildcardQ, hasWildcard := 0.0, false
+	for _, enc := range encodings {
+		if enc.name == name {
+			return enc.q, true
+		}
+		if enc.name == "*" {
+			wildcardQ, hasWildcard = enc.q, true
+		}
+	}
+	if hasWildcard {
+		return wildcardQ, true
+	}
+	return 0, false
+}
+
+// preferredEncoding picks br or gzip per the client's Accept-Encoding
+// q-values (br winning ties), honoring an explicit "q=0" as a
+// rejection of that coding. It returns "" if neither is acceptable.
+func preferredEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	encodings := parseAcceptEncoding(header)
+	brQ, brOK := encodingQ(encodings, "br")
+	gzipQ, gzipOK := encodingQ(encodings, "gzip")
+
+	switch {
+	case brOK && brQ > 0 && brQ >= gzipQ:
+		return "br"
+	case gzipOK && gzipQ > 0:
+		return "gzip"
+	default:
+		return ""
+	}
+}
+
+// WithCompression picks gzip or brotli based on the request's
+// Accept-Encoding header and its q-values, sets Content-Encoding and
+// Vary, and streams the render through a pooled compressor. If the
+// client doesn't advertise support for either, it only adds Vary and
+// leaves the body uncompressed.
+func WithCompression() RenderOption {
+	return func(w http.ResponseWriter, r *http.Request, status int, obj interface{}) (http.ResponseWriter, bool, error) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		switch preferredEncoding(r.Header.Get("Accept-Encoding")) {
+		case "br":
+			w.Header().Set("Content-Encoding", "br")
+			bw := brotliWriterPool.Get().(*brotli.Writer)
+			bw.Reset(w)
+			return &compressWriter{ResponseWriter: w, zw: bw, release: func() { brotliWriterPool.Put(bw) }}, false, nil
+
+		case "gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			gw := gzipWriterPool.Get().(*gzip.Writer)
+			gw.Reset(w)
+			return &compressWriter{ResponseWriter: w, zw: gw, release: func() { gzipWriterPool.Put(gw) }}, false, nil
+
+		default:
+			return w, false, nil
+		}
+	}
+}
+
+// bufferingWriter captures the status and body a Renderer writes so a
+// RenderOption can inspect or rewrite them before anything reaches the
+// client.
+type bufferingWriter struct {
+	http.ResponseWriter
+	status      int
+	buf         bytes.Buffer
+	wroteHeader bool
+}
+
+func (b *bufferingWriter) WriteHeader(status int) {
+	b.status = status
+	b.wroteHeader = true
+}
+
+func (b *bufferingWriter) Write(p []byte) (int, error) {
+	if !b.wroteHeader {
+		b.status = http.StatusOK
+	}
+	return b.buf.Write(p)
+}
+
+// WithETag buffers the response, computes a strong SHA-256 ETag over
+// the body, and answers 304 Not Modified when it matches the request's
+// If-None-Match.
+func WithETag() RenderOption {
+	return func(w http.ResponseWriter, r *http.Request, status int, obj interface{}) (http.ResponseWriter, bool, error) {
+		bw := &bufferingWriter{ResponseWriter: w}
+		return &etagWriter{bufferingWriter: bw, req: r}, false, nil
+	}
+}
+
+type etagWriter struct {
+	*bufferingWriter
+	req *http.Request
+}
+
+func (e *etagWriter) Close() error {
+	sum := sha256.Sum256(e.buf.Bytes())
+	etag := fmt.Sprintf("%q", fmt.Sprintf("%x", sum))
+
+	w := e.bufferingWriter.ResponseWriter
+	w.Header().Set("ETag", etag)
+
+	if match := e.req.Header.Get("If-None-Match"); match != "" && match == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return closeIfCloser(w)
+	}
+
+	w.WriteHeader(e.status)
+	_, err := w.Write(e.buf.Bytes())
+	if cerr := closeIfCloser(w); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// WithLastModified sets the Last-Modified response header to t and
+// answers 304 Not Modified when the request's If-Modified-Since is at
+// or after t.
+func WithLastModified(t time.Time) RenderOption {
+	return func(w http.ResponseWriter, r *http.Request, status int, obj interface{}) (http.ResponseWriter, bool, error) {
+		t = t.Truncate(time.Second)
+		w.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+
+		if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+			if since, err := time.Parse(http.TimeFormat, ims); err == nil && !t.After(since) {
+				w.WriteHeader(http.StatusNotModified)
+				return w, true, nil
+			}
+		}
+
+		return w, false, nil
+	}
+}
@@ -0,0 +1,104 @@
+package render
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRenderCompressionAndETagChainCloseProperly(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	obj := map[string]string{"msg": "hi"}
+	if err := Render(w, req, http.StatusOK, obj, WithCompression(), WithETag()); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	resp := w.Result()
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip Content-Encoding, got %q", resp.Header.Get("Content-Encoding"))
+	}
+
+	gr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v (body was not properly closed/flushed)", err)
+	}
+	defer gr.Close()
+
+	body, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("reading gzip body: %v", err)
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("unmarshaling decompressed body: %v", err)
+	}
+	if got["msg"] != "hi" {
+		t.Fatalf("expected msg=hi, got %v", got)
+	}
+}
+
+func TestPreferredEncodingHonorsQValues(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"gzip;q=1.0, br;q=0.1", "gzip"},
+		{"br;q=1.0, gzip;q=0.1", "br"},
+		{"gzip, br", "br"},
+		{"gzip;q=0", ""},
+		{"gzip;q=0, br;q=0", ""},
+		{"deflate", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := preferredEncoding(tt.header); got != tt.want {
+			t.Errorf("preferredEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestWithCompressionSkipsExplicitlyRejectedGzip(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0")
+	w := httptest.NewRecorder()
+
+	if err := Render(w, req, http.StatusOK, map[string]string{"msg": "hi"}, WithCompression()); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	if ce := w.Result().Header.Get("Content-Encoding"); ce != "" {
+		t.Fatalf("expected no Content-Encoding when gzip is explicitly rejected, got %q", ce)
+	}
+}
+
+func TestETagNotModifiedShortCircuits(t *testing.T) {
+	obj := map[string]string{"msg": "hi"}
+
+	first := httptest.NewRequest("GET", "/", nil)
+	w1 := httptest.NewRecorder()
+	if err := Render(w1, first, http.StatusOK, obj, WithETag()); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	etag := w1.Result().Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header")
+	}
+
+	second := httptest.NewRequest("GET", "/", nil)
+	second.Header.Set("If-None-Match", etag)
+	w2 := httptest.NewRecorder()
+	if err := Render(w2, second, http.StatusOK, obj, WithETag()); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if w2.Result().StatusCode != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", w2.Result().StatusCode)
+	}
+}
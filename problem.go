@@ -0,0 +1,103 @@
+package render
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemDetails implements RFC 7807 "Problem Details for HTTP APIs". It
+// replaces the old bare-bones Error type as the body produced by the
+// StatusXxx helpers.
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Extensions are marshaled inline alongside the fields above, per
+	// the "extension members" section of RFC 7807. encoding/xml can't
+	// represent a map[string]interface{}, so extensions don't survive
+	// on the XML wire; they're only marshaled by JSON.
+	Extensions map[string]interface{} `json:"-" xml:"-"`
+}
+
+// Error implements the error interface so a *ProblemDetails can be
+// returned anywhere the package previously returned a *Error.
+func (p *ProblemDetails) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	return p.Title
+}
+
+// NewProblem builds a ProblemDetails for the given status code. id is
+// used as the "type" URI; callers that don't have a dereferenceable URI
+// for the problem type should pass "about:blank".
+func NewProblem(status int, id, title string) *ProblemDetails {
+	return &ProblemDetails{
+		Type:   id,
+		Title:  title,
+		Status: status,
+	}
+}
+
+// WithDetail sets the human-readable Detail explaining this occurrence
+// of the problem.
+func (p *ProblemDetails) WithDetail(detail string) *ProblemDetails {
+	p.Detail = detail
+	return p
+}
+
+// WithInstance sets the URI identifying this specific occurrence of the
+// problem. Render defaults this to r.URL.Path when unset.
+func (p *ProblemDetails) WithInstance(instance string) *ProblemDetails {
+	p.Instance = instance
+	return p
+}
+
+// WithExtension attaches an extension member that will be marshaled
+// inline alongside the standard Problem Details fields.
+func (p *ProblemDetails) WithExtension(key string, value interface{}) *ProblemDetails {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]interface{})
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// MarshalJSON flattens Extensions into the object alongside the fixed
+// Problem Details members.
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		out[k] = v
+	}
+	if p.Type != "" {
+		out["type"] = p.Type
+	}
+	if p.Title != "" {
+		out["title"] = p.Title
+	}
+	if p.Status != 0 {
+		out["status"] = p.Status
+	}
+	if p.Detail != "" {
+		out["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		out["instance"] = p.Instance
+	}
+	return json.Marshal(out)
+}
+
+// problemHandlers lets callers override how a given status code is
+// rendered, mirroring the per-status handler registry in
+// kevinburke/rest.
+var problemHandlers = map[int]http.Handler{}
+
+// RegisterProblemHandler overrides the rendering of responses with the
+// given status code, bypassing the configured Renderer entirely.
+func RegisterProblemHandler(status int, handler http.Handler) {
+	problemHandlers[status] = handler
+}
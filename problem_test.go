@@ -0,0 +1,16 @@
+package render
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestProblemDetailsMarshalsAsXML(t *testing.T) {
+	problem := NewProblem(400, "about:blank", "Bad Request").
+		WithDetail("missing field").
+		WithExtension("field", "name")
+
+	if _, err := xml.Marshal(problem); err != nil {
+		t.Fatalf("xml.Marshal returned error: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+/* Package protobuf registers a Protocol Buffers Renderer with
+github.com/webpkgs/render. Import it for its side effect to make
+application/x-protobuf available to Render's Accept negotiation:
+
+	import _ "github.com/webpkgs/render/protobuf"
+*/
+package protobuf
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/webpkgs/render"
+)
+
+func init() {
+	render.Register("application/x-protobuf", new(Protobuf))
+}
+
+// Protobuf implements render.Renderer by marshaling obj with
+// github.com/golang/protobuf/proto. obj must implement proto.Message.
+type Protobuf struct{}
+
+// Render implements render.Renderer. It returns an error, without
+// writing anything, if obj does not implement proto.Message.
+func (p *Protobuf) Render(w http.ResponseWriter, r *http.Request, httpCode int, obj interface{}) error {
+	msg, ok := obj.(proto.Message)
+	if !ok {
+		return fmt.Errorf("render/protobuf: %T does not implement proto.Message", obj)
+	}
+
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}
+	w.Header().Set("Date", time.Now().Format(time.RFC1123Z))
+
+	w.WriteHeader(httpCode)
+
+	_, err = w.Write(body)
+	return err
+}
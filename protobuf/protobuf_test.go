@@ -0,0 +1,39 @@
+package protobuf
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProtobufRenderEncodesProtoMessage(t *testing.T) {
+	p := new(Protobuf)
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	msg := wrapperspb.String("hi")
+	if err := p.Render(w, req, 200, msg); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	var got wrapperspb.StringValue
+	if err := proto.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.Value != "hi" {
+		t.Fatalf("expected hi, got %q", got.Value)
+	}
+}
+
+func TestProtobufRenderRejectsNonProtoMessage(t *testing.T) {
+	p := new(Protobuf)
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	err := p.Render(w, req, 200, map[string]string{"msg": "hi"})
+	if err == nil {
+		t.Fatal("expected an error for a non-proto.Message obj")
+	}
+}
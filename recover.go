@@ -0,0 +1,34 @@
+package render
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Recover converts a panic in next into a Problem Details 500 response
+// rendered through the configured DefaultStatusRenderer, instead of
+// letting it unwind into the net/http server's default (bare,
+// connection-closing) panic handling. It does not buffer next's
+// output, so if next has already written a status or body before
+// panicking, the 500 gets appended to (or fails to overwrite) what was
+// already sent; use RenderBuffered in next if that matters.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				// net/http's documented sentinel for silently aborting
+				// a handler; re-panic so the server's own handling
+				// (no logging, no response) takes over.
+				panic(rec)
+			}
+
+			DefaultStatusRenderer.StatusInternalServerError(w, r, fmt.Sprint(rec))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -39,20 +39,131 @@ func Register(contentType string, handler Renderer) {
 	registeredContentType = append(registeredContentType, contentType)
 }
 
-// Render selects a valid Renderer based on the accept header of the request
-func Render(w http.ResponseWriter, r *http.Request, httpStatus int, obj interface{}) error {
-	accepts := r.Header.Get("Accept")
-
-	for _, accept := range strings.Split(accepts, ",") {
-		contentType := strings.Split(accept, ";")[0]
-		if handler, ok := register[contentType]; ok {
-			return handler.Render(w, r, httpStatus, obj)
+// Render negotiates a Renderer based on the Accept header of the
+// request and delegates to it. Any RenderOptions are applied to the
+// ResponseWriter first, so e.g. compression or ETag handling happens
+// without the Renderer itself knowing about it.
+func Render(w http.ResponseWriter, r *http.Request, httpStatus int, obj interface{}, opts ...RenderOption) error {
+	if problem, ok := obj.(*ProblemDetails); ok && problem.Instance == "" {
+		problem.Instance = r.URL.Path
+	}
+
+	if handler, ok := problemHandlers[httpStatus]; ok {
+		handler.ServeHTTP(w, r)
+		return nil
+	}
+
+	offered := concreteContentTypes()
+
+	contentType, params, ok := Negotiate(r.Header.Get("Accept"), offered)
+	if !ok {
+		problem := NewProblem(http.StatusNotAcceptable, "about:blank", "Not Acceptable").
+			WithDetail(fmt.Sprint("Accept header must be set to one of ", strings.Join(offered, ", "))).
+			WithExtension("offered", offered)
+		return DefaultJSON.Render(w, r, http.StatusNotAcceptable, problem)
+	}
+
+	negotiated := contentType
+	if _, isProblem := obj.(*ProblemDetails); isProblem {
+		negotiated = asProblemContentType(negotiated)
+	}
+	if charset, hasCharset := params["charset"]; hasCharset {
+		negotiated += ";charset=" + charset
+	}
+	w.Header().Set("Content-Type", negotiated)
+
+	rw, done, err := applyOptions(w, r, httpStatus, obj, opts)
+	if err != nil {
+		return err
+	}
+	if done {
+		return closeIfCloser(rw)
+	}
+
+	renderErr := register[contentType].Render(rw, r, httpStatus, obj)
+	// Without opts, rw is the caller's own writer; closing it is left to
+	// whoever wrapped it (e.g. handlerHelper.render).
+	if len(opts) == 0 {
+		return renderErr
+	}
+	if closeErr := closeIfCloser(rw); closeErr != nil && renderErr == nil {
+		renderErr = closeErr
+	}
+	return renderErr
+}
+
+// RenderBuffered behaves like Render but encodes into an in-memory
+// buffer first. The built-in renderers (JSON included) call
+// WriteHeader before they start encoding, so once encoding begins the
+// status code is already on the wire and a later encoding failure
+// can't change it to a 500 — it can only abort the body half-written.
+// RenderBuffered avoids that by rendering into a buffer and only
+// writing httpStatus and the body to w once encoding succeeds; if it
+// fails instead, nothing reaches the client and a Problem Details 500
+// is rendered in its place.
+func RenderBuffered(w http.ResponseWriter, r *http.Request, httpStatus int, obj interface{}, opts ...RenderOption) error {
+	// RenderOptions like WithCompression set headers (Content-Encoding,
+	// Vary) on w as soon as they run, before we know whether encoding
+	// will even succeed. Snapshot so a failed attempt can't leave those
+	// headers on the wire for the option-free fallback response below.
+	headersBefore := cloneHeader(w.Header())
+
+	bw := &bufferingWriter{ResponseWriter: w}
+
+	if err := Render(bw, r, httpStatus, obj, opts...); err != nil {
+		resetHeader(w.Header(), headersBefore)
+
+		problem := NewProblem(http.StatusInternalServerError, "about:blank", "Internal Server Error").
+			WithDetail(err.Error())
+		return Render(w, r, http.StatusInternalServerError, problem)
+	}
+
+	w.WriteHeader(bw.status)
+	_, err := w.Write(bw.buf.Bytes())
+	return err
+}
+
+// cloneHeader returns a deep copy of h.
+func cloneHeader(h http.Header) http.Header {
+	clone := make(http.Header, len(h))
+	for k, v := range h {
+		clone[k] = append([]string(nil), v...)
+	}
+	return clone
+}
+
+// resetHeader replaces h's contents with a copy of snapshot.
+func resetHeader(h http.Header, snapshot http.Header) {
+	for k := range h {
+		delete(h, k)
+	}
+	for k, v := range snapshot {
+		h[k] = append([]string(nil), v...)
+	}
+}
+
+// concreteContentTypes returns the registered content types a client
+// can actually ask for, i.e. everything except the "*/*" fallback
+// entry.
+func concreteContentTypes() []string {
+	types := make([]string, 0, len(registeredContentType))
+	for _, ct := range registeredContentType {
+		if ct == "*/*" {
+			continue
 		}
+		types = append(types, ct)
 	}
+	return types
+}
 
-	return DefaultJSON.Render(w, r,
-		http.StatusNotAcceptable,
-		mkerror(1, fmt.Sprint("Accept header must be set to one of ", strings.Join(registeredContentType, ","))))
+// asProblemContentType turns e.g. "application/json" into
+// "application/problem+json" for Problem Details bodies.
+func asProblemContentType(contentType string) string {
+	idx := strings.Index(contentType, "/")
+	if idx < 0 {
+		return contentType
+	}
+	return contentType[:idx+1] + "problem+" + contentType[idx+1:]
 }
 
 // JSON implements the render.Renderer
@@ -60,7 +171,13 @@ type JSON struct{}
 
 // Render implements render.Renderer
 func (s *JSON) Render(w http.ResponseWriter, r *http.Request, httpCode int, obj interface{}) error {
-	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	if w.Header().Get("Content-Type") == "" {
+		if _, ok := obj.(*ProblemDetails); ok {
+			w.Header().Set("Content-Type", "application/problem+json;charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/json;charset=utf-8")
+		}
+	}
 	w.Header().Set("Date", time.Now().Format(time.RFC1123Z))
 
 	w.WriteHeader(httpCode)
@@ -76,9 +193,9 @@ func (s *JSON) Render(w http.ResponseWriter, r *http.Request, httpCode int, obj
 type StatusRenderer interface {
 	Renderer
 	// 2XX
-	StatusOK(http.ResponseWriter, *http.Request, interface{}) error
-	StatusCreated(http.ResponseWriter, *http.Request, interface{}) error
-	StatusAccepted(http.ResponseWriter, *http.Request, interface{}) error
+	StatusOK(http.ResponseWriter, *http.Request, interface{}, ...RenderOption) error
+	StatusCreated(http.ResponseWriter, *http.Request, interface{}, ...RenderOption) error
+	StatusAccepted(http.ResponseWriter, *http.Request, interface{}, ...RenderOption) error
 
 	// 4XX
 	StatusBadRequest(http.ResponseWriter, *http.Request, ...interface{}) error
@@ -90,7 +207,8 @@ type StatusRenderer interface {
 	// StatusTooManyRequest(http.ResponseWriter, *http.Request, ...interface{}) error
 
 	// 5XX
-	//	Error(http.ResponseWriter, *http.Request, int, ...interface{}) error
+	StatusInternalServerError(http.ResponseWriter, *http.Request, ...interface{}) error
+	StatusServiceUnavailable(http.ResponseWriter, *http.Request, ...interface{}) error
 }
 
 // this struct uses the module's render
@@ -104,16 +222,12 @@ func New(r Renderer) StatusRenderer {
 	return &handlerHelper{r}
 }
 
-type Error struct {
-	Message string `json:"message"`
-}
-
-func (e *Error) Error() string {
-	return e.Message
-}
-
-func mkerror(callDepth int, msg string) error {
-	return &Error{msg}
+// mkerror builds the ProblemDetails body for the StatusXxx helpers.
+// status becomes both the HTTP status and the Problem Details "status"
+// member; msg becomes "detail" since the helpers don't have a separate
+// human title to offer.
+func mkerror(status int, msg string) error {
+	return NewProblem(status, "about:blank", "").WithDetail(msg)
 }
 
 type handlerHelper struct {
@@ -122,75 +236,93 @@ type handlerHelper struct {
 
 // Renderer
 func (h *handlerHelper) Render(w http.ResponseWriter, r *http.Request, httpStatus int, obj interface{}) error {
-	return h.Render(w, r, httpStatus, obj)
+	return h.r.Render(w, r, httpStatus, obj)
+}
+
+// render applies opts to w before delegating to h.r, so RenderOptions
+// work the same regardless of which Renderer this helper wraps.
+func (h *handlerHelper) render(w http.ResponseWriter, r *http.Request, httpStatus int, obj interface{}, opts []RenderOption) error {
+	rw, done, err := applyOptions(w, r, httpStatus, obj, opts)
+	if err != nil {
+		return err
+	}
+	if done {
+		return closeIfCloser(rw)
+	}
+
+	renderErr := h.r.Render(rw, r, httpStatus, obj)
+	if closeErr := closeIfCloser(rw); closeErr != nil && renderErr == nil {
+		renderErr = closeErr
+	}
+	return renderErr
 }
 
 // 200
-func (h *handlerHelper) StatusOK(w http.ResponseWriter, r *http.Request, obj interface{}) error {
-	return h.r.Render(w, r, http.StatusOK, obj)
+func (h *handlerHelper) StatusOK(w http.ResponseWriter, r *http.Request, obj interface{}, opts ...RenderOption) error {
+	return h.render(w, r, http.StatusOK, obj, opts)
 }
 
 // 201
-func (h *handlerHelper) StatusCreated(w http.ResponseWriter, r *http.Request, obj interface{}) error {
-	return h.r.Render(w, r, http.StatusCreated, obj)
+func (h *handlerHelper) StatusCreated(w http.ResponseWriter, r *http.Request, obj interface{}, opts ...RenderOption) error {
+	return h.render(w, r, http.StatusCreated, obj, opts)
 }
 
 // 202
-func (h *handlerHelper) StatusAccepted(w http.ResponseWriter, r *http.Request, obj interface{}) error {
-	return h.r.Render(w, r, http.StatusAccepted, obj)
+func (h *handlerHelper) StatusAccepted(w http.ResponseWriter, r *http.Request, obj interface{}, opts ...RenderOption) error {
+	return h.render(w, r, http.StatusAccepted, obj, opts)
 }
 
 // 400
 func (h *handlerHelper) StatusBadRequest(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
-	return h.r.Render(w, r, http.StatusBadRequest, mkerror(1, fmt.Sprint(args)))
+	return h.r.Render(w, r, http.StatusBadRequest, mkerror(http.StatusBadRequest, fmt.Sprint(args...)))
 }
 
 // 401
 func (h *handlerHelper) StatusUnauthorized(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
-	return h.r.Render(w, r, http.StatusUnauthorized, mkerror(1, fmt.Sprint(args)))
+	return h.r.Render(w, r, http.StatusUnauthorized, mkerror(http.StatusUnauthorized, fmt.Sprint(args...)))
 }
 
 // 402
 func (h *handlerHelper) StatusForbidden(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
-	return h.r.Render(w, r, http.StatusForbidden, mkerror(1, fmt.Sprint(args)))
+	return h.r.Render(w, r, http.StatusForbidden, mkerror(http.StatusForbidden, fmt.Sprint(args...)))
 }
 
 // 403
 func (h *handlerHelper) StatusMethodNotAllowed(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
-	return h.r.Render(w, r, http.StatusMethodNotAllowed, mkerror(1, fmt.Sprint(args)))
+	return h.r.Render(w, r, http.StatusMethodNotAllowed, mkerror(http.StatusMethodNotAllowed, fmt.Sprint(args...)))
 }
 
 // 404
 func (h *handlerHelper) StatusNotFound(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
-	return h.r.Render(w, r, http.StatusNotFound, mkerror(1, fmt.Sprint(args)))
+	return h.r.Render(w, r, http.StatusNotFound, mkerror(http.StatusNotFound, fmt.Sprint(args...)))
 }
 
 // 500
-func (h *handlerHelper) InternalServerError(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
-	return h.r.Render(w, r, http.StatusInternalServerError, mkerror(1, fmt.Sprint(args)))
+func (h *handlerHelper) StatusInternalServerError(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
+	return h.r.Render(w, r, http.StatusInternalServerError, mkerror(http.StatusInternalServerError, fmt.Sprint(args...)))
 }
 
-// 501
-func (h *handlerHelper) ServiceUnavailable(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
-	return h.r.Render(w, r, http.StatusServiceUnavailable, mkerror(1, fmt.Sprint(args)))
+// 503
+func (h *handlerHelper) StatusServiceUnavailable(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
+	return h.r.Render(w, r, http.StatusServiceUnavailable, mkerror(http.StatusServiceUnavailable, fmt.Sprint(args...)))
 }
 
 // DefaultStatu
 var DefaultStatusRenderer = handlerHelper{&moduleRender{}}
 
 // 200
-func StatusOK(w http.ResponseWriter, r *http.Request, obj interface{}) error {
-	return DefaultStatusRenderer.StatusOK(w, r, obj)
+func StatusOK(w http.ResponseWriter, r *http.Request, obj interface{}, opts ...RenderOption) error {
+	return DefaultStatusRenderer.StatusOK(w, r, obj, opts...)
 }
 
 // 201
-func StatusCreated(w http.ResponseWriter, r *http.Request, obj interface{}) error {
-	return DefaultStatusRenderer.StatusCreated(w, r, obj)
+func StatusCreated(w http.ResponseWriter, r *http.Request, obj interface{}, opts ...RenderOption) error {
+	return DefaultStatusRenderer.StatusCreated(w, r, obj, opts...)
 }
 
 // 202
-func StatusAccepted(w http.ResponseWriter, r *http.Request, obj interface{}) error {
-	return DefaultStatusRenderer.StatusAccepted(w, r, obj)
+func StatusAccepted(w http.ResponseWriter, r *http.Request, obj interface{}, opts ...RenderOption) error {
+	return DefaultStatusRenderer.StatusAccepted(w, r, obj, opts...)
 }
 
 // 400
@@ -217,3 +349,13 @@ func StatusMethodNotAllowed(w http.ResponseWriter, r *http.Request, args ...inte
 func StatusNotFound(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
 	return DefaultStatusRenderer.StatusNotFound(w, r, args...)
 }
+
+// 500
+func StatusInternalServerError(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
+	return DefaultStatusRenderer.StatusInternalServerError(w, r, args...)
+}
+
+// 503
+func StatusServiceUnavailable(w http.ResponseWriter, r *http.Request, args ...interface{}) error {
+	return DefaultStatusRenderer.StatusServiceUnavailable(w, r, args...)
+}
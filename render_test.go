@@ -0,0 +1,99 @@
+package render
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stubRenderer records the status/obj it was asked to render so tests
+// can assert handlerHelper actually delegates instead of recursing on
+// itself.
+type stubRenderer struct {
+	calls  int
+	status int
+	obj    interface{}
+}
+
+func (s *stubRenderer) Render(w http.ResponseWriter, r *http.Request, httpStatus int, obj interface{}) error {
+	s.calls++
+	s.status = httpStatus
+	s.obj = obj
+	w.WriteHeader(httpStatus)
+	return nil
+}
+
+func TestHandlerHelperRenderDelegatesToUnderlyingRenderer(t *testing.T) {
+	stub := &stubRenderer{}
+	h := New(stub)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	if err := h.Render(w, req, http.StatusTeapot, "hello"); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	if stub.calls != 1 {
+		t.Fatalf("expected exactly one call to the underlying Renderer, got %d", stub.calls)
+	}
+	if stub.status != http.StatusTeapot {
+		t.Fatalf("expected status %d, got %d", http.StatusTeapot, stub.status)
+	}
+}
+
+func TestHandlerHelperStatusOKDelegates(t *testing.T) {
+	stub := &stubRenderer{}
+	h := New(stub)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	if err := h.StatusOK(w, req, "hello"); err != nil {
+		t.Fatalf("StatusOK returned error: %v", err)
+	}
+
+	if stub.calls != 1 || stub.status != http.StatusOK {
+		t.Fatalf("expected a single 200 call, got %d calls with status %d", stub.calls, stub.status)
+	}
+}
+
+func TestRecoverRendersProblemDetails(t *testing.T) {
+	handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected a Problem Details content type, got %q", ct)
+	}
+}
+
+// unmarshalable fails to encode as JSON, simulating a mid-stream
+// encoding failure.
+type unmarshalable struct {
+	Ch chan int `json:"ch"`
+}
+
+func TestRenderBufferedReportsEncodeFailureAsInternalServerError(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+
+	err := RenderBuffered(w, req, http.StatusOK, &unmarshalable{Ch: make(chan int)})
+	if err != nil {
+		t.Fatalf("RenderBuffered returned error: %v", err)
+	}
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d after an encoding failure, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
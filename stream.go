@@ -0,0 +1,170 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// StreamRenderer is implemented by renderers that can serve an
+// unbounded channel of values rather than a single object.
+type StreamRenderer interface {
+	RenderStream(w http.ResponseWriter, r *http.Request, ch <-chan interface{}) error
+}
+
+// SSEEvent lets a value streamed over an SSE renderer supply its own
+// "id:" and "event:" fields alongside the default "data:" line.
+type SSEEvent interface {
+	SSEID() string
+	SSEEventType() string
+}
+
+func init() {
+	Register("application/x-ndjson", new(NDJSON))
+	Register("application/jsonlines", new(JSONLines))
+	Register("text/event-stream", NewSSE(30*time.Second))
+}
+
+// writeFlush writes b and flushes immediately if the ResponseWriter
+// supports it.
+func writeFlush(w http.ResponseWriter, b []byte) error {
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// NDJSON implements StreamRenderer by writing one JSON-encoded object
+// per line, flushing after each.
+type NDJSON struct{}
+
+// Render implements render.Renderer for a single, non-streamed value.
+func (n *NDJSON) Render(w http.ResponseWriter, r *http.Request, httpCode int, obj interface{}) error {
+	ch := make(chan interface{}, 1)
+	ch <- obj
+	close(ch)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(httpCode)
+
+	return n.RenderStream(w, r, ch)
+}
+
+// RenderStream implements StreamRenderer.
+func (n *NDJSON) RenderStream(w http.ResponseWriter, r *http.Request, ch <-chan interface{}) error {
+	enc := json.NewEncoder(w)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case obj, open := <-ch:
+			if !open {
+				return nil
+			}
+			if err := enc.Encode(obj); err != nil {
+				return err
+			}
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+	}
+}
+
+// JSONLines is identical to NDJSON but registered for the
+// application/jsonlines media type some clients expect instead.
+type JSONLines struct {
+	NDJSON
+}
+
+// Render implements render.Renderer for a single, non-streamed value.
+func (j *JSONLines) Render(w http.ResponseWriter, r *http.Request, httpCode int, obj interface{}) error {
+	ch := make(chan interface{}, 1)
+	ch <- obj
+	close(ch)
+
+	w.Header().Set("Content-Type", "application/jsonlines")
+	w.WriteHeader(httpCode)
+
+	return j.RenderStream(w, r, ch)
+}
+
+// SSE implements StreamRenderer using the text/event-stream format.
+// It sends a ": keepalive" comment every KeepAlive interval so
+// intermediate proxies don't time out an idle connection.
+type SSE struct {
+	KeepAlive time.Duration
+}
+
+// NewSSE returns an SSE renderer that sends a keepalive comment every
+// keepAlive interval.
+func NewSSE(keepAlive time.Duration) *SSE {
+	return &SSE{KeepAlive: keepAlive}
+}
+
+// Render implements render.Renderer for a single, non-streamed value.
+func (s *SSE) Render(w http.ResponseWriter, r *http.Request, httpCode int, obj interface{}) error {
+	ch := make(chan interface{}, 1)
+	ch <- obj
+	close(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(httpCode)
+
+	return s.RenderStream(w, r, ch)
+}
+
+// RenderStream implements StreamRenderer.
+func (s *SSE) RenderStream(w http.ResponseWriter, r *http.Request, ch <-chan interface{}) error {
+	keepAlive := s.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+
+		case <-ticker.C:
+			if err := writeFlush(w, []byte(": keepalive\n\n")); err != nil {
+				return err
+			}
+
+		case obj, open := <-ch:
+			if !open {
+				return nil
+			}
+
+			data, err := json.Marshal(obj)
+			if err != nil {
+				return err
+			}
+
+			var b []byte
+			if ev, ok := obj.(SSEEvent); ok {
+				if id := ev.SSEID(); id != "" {
+					b = append(b, []byte(fmt.Sprintf("id: %s\n", id))...)
+				}
+				if et := ev.SSEEventType(); et != "" {
+					b = append(b, []byte(fmt.Sprintf("event: %s\n", et))...)
+				}
+			}
+			b = append(b, []byte(fmt.Sprintf("data: %s\n\n", data))...)
+
+			if err := writeFlush(w, b); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNDJSONRenderStreamWritesOneObjectPerLine(t *testing.T) {
+	n := new(NDJSON)
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	ch := make(chan interface{}, 2)
+	ch <- map[string]int{"n": 1}
+	ch <- map[string]int{"n": 2}
+	close(ch)
+
+	if err := n.RenderStream(w, req, ch); err != nil {
+		t.Fatalf("RenderStream returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), w.Body.String())
+	}
+
+	var first map[string]int
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("decoding first line: %v", err)
+	}
+	if first["n"] != 1 {
+		t.Fatalf("expected n=1, got %v", first)
+	}
+}
+
+func TestNDJSONRenderStreamStopsOnContextCancel(t *testing.T) {
+	n := new(NDJSON)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	ch := make(chan interface{})
+	cancel()
+
+	if err := n.RenderStream(w, req, ch); err != nil {
+		t.Fatalf("RenderStream returned error: %v", err)
+	}
+}
+
+func TestSSERenderStreamFormatsDataLines(t *testing.T) {
+	s := NewSSE(time.Hour)
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	ch := make(chan interface{}, 1)
+	ch <- map[string]int{"n": 1}
+	close(ch)
+
+	if err := s.RenderStream(w, req, ch); err != nil {
+		t.Fatalf("RenderStream returned error: %v", err)
+	}
+
+	if !strings.Contains(w.Body.String(), "data: {\"n\":1}\n\n") {
+		t.Fatalf("expected an SSE data line, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,79 @@
+/* Package xml registers an XML Renderer with github.com/webpkgs/render. Import
+it for its side effect to make application/xml and text/xml available to
+Render's Accept negotiation:
+
+	import _ "github.com/webpkgs/render/xml"
+*/
+package xml
+
+import (
+	gxml "encoding/xml"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/webpkgs/render"
+)
+
+func init() {
+	x := New("response")
+	render.Register("application/xml", x)
+	render.Register("text/xml", x)
+}
+
+// XML implements render.Renderer by marshaling obj with encoding/xml.
+// Values that don't already carry an XMLName are wrapped in an element
+// named Root.
+type XML struct {
+	// Root is the element name used to wrap values that don't declare
+	// their own XMLName.
+	Root string
+
+	// Prolog, when true, writes the `<?xml version="1.0"
+	// encoding="UTF-8"?>` declaration before the document.
+	Prolog bool
+}
+
+// New returns an XML renderer that wraps untagged values in an element
+// named root and omits the XML prolog.
+func New(root string) *XML {
+	return &XML{Root: root}
+}
+
+// Render implements render.Renderer.
+func (x *XML) Render(w http.ResponseWriter, r *http.Request, httpCode int, obj interface{}) error {
+	if w.Header().Get("Content-Type") == "" {
+		if _, ok := obj.(*render.ProblemDetails); ok {
+			w.Header().Set("Content-Type", "application/problem+xml;charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/xml;charset=utf-8")
+		}
+	}
+	w.Header().Set("Date", time.Now().Format(time.RFC1123Z))
+
+	w.WriteHeader(httpCode)
+
+	if x.Prolog {
+		if _, err := w.Write([]byte(gxml.Header)); err != nil {
+			return err
+		}
+	}
+
+	enc := gxml.NewEncoder(w)
+
+	if _, named := obj.(gxml.Marshaler); named {
+		return enc.Encode(obj)
+	}
+	if v := reflect.Indirect(reflect.ValueOf(obj)); v.Kind() == reflect.Struct {
+		if _, named := v.Type().FieldByName("XMLName"); named {
+			return enc.Encode(obj)
+		}
+	}
+
+	root := x.Root
+	if root == "" {
+		root = "Root"
+	}
+
+	return enc.EncodeElement(obj, gxml.StartElement{Name: gxml.Name{Local: root}})
+}
@@ -0,0 +1,44 @@
+package xml
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/webpkgs/render"
+)
+
+type greeting struct {
+	Msg string
+}
+
+func TestProblemXMLIsNotOfferedForOrdinaryObjects(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "application/problem+xml")
+	w := httptest.NewRecorder()
+
+	if err := render.Render(w, req, http.StatusOK, &greeting{Msg: "hi"}); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	if w.Result().StatusCode != http.StatusNotAcceptable {
+		t.Fatalf("expected 406 Not Acceptable since application/problem+xml isn't independently offered, got %d", w.Result().StatusCode)
+	}
+}
+
+func TestProblemXMLIsUsedOnlyForProblemDetails(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+
+	problem := render.NewProblem(http.StatusBadRequest, "about:blank", "Bad Request")
+	if err := render.Render(w, req, http.StatusBadRequest, problem); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	ct := w.Result().Header.Get("Content-Type")
+	if !strings.HasPrefix(ct, "application/problem+xml") {
+		t.Fatalf("expected application/problem+xml for a ProblemDetails body, got %q", ct)
+	}
+}